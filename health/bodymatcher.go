@@ -0,0 +1,80 @@
+/*
+ * Copyright 2022 Michael Graff.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License")
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// BodyMatcher inspects a checker's raw response body and returns an error
+// if it does not meet expectations.
+type BodyMatcher func(body []byte) error
+
+// RegexBodyMatcher returns a BodyMatcher that requires the response body
+// to match pattern.
+func RegexBodyMatcher(pattern string) (BodyMatcher, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return func(body []byte) error {
+		if !re.Match(body) {
+			return fmt.Errorf("response body did not match pattern %q", pattern)
+		}
+		return nil
+	}, nil
+}
+
+// JSONFieldBodyMatcher returns a BodyMatcher that decodes the response
+// body as JSON and requires the field at the given dot-separated path
+// (e.g. "status.ok") to equal want.
+func JSONFieldBodyMatcher(path string, want any) BodyMatcher {
+	return func(body []byte) error {
+		var doc any
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return fmt.Errorf("decoding JSON response: %w", err)
+		}
+		got, err := jsonFieldLookup(doc, path)
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(got, want) {
+			return fmt.Errorf("field %q = %v, want %v", path, got, want)
+		}
+		return nil
+	}
+}
+
+func jsonFieldLookup(doc any, path string) (any, error) {
+	cur := doc
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("field %q: %q is not an object", path, part)
+		}
+		v, ok := m[part]
+		if !ok {
+			return nil, fmt.Errorf("field %q: %q not found", path, part)
+		}
+		cur = v
+	}
+	return cur, nil
+}
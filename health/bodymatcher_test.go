@@ -0,0 +1,49 @@
+/*
+ * Copyright 2022 Michael Graff.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License")
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RegexBodyMatcher(t *testing.T) {
+	matcher, err := RegexBodyMatcher(`^ok$`)
+	assert.NoError(t, err)
+	assert.NoError(t, matcher([]byte("ok")))
+	assert.Error(t, matcher([]byte("not ok")))
+
+	_, err = RegexBodyMatcher(`(`)
+	assert.Error(t, err, "an invalid pattern should fail at construction")
+}
+
+func Test_JSONFieldBodyMatcher(t *testing.T) {
+	body := []byte(`{"status":{"ok":true}}`)
+
+	matcher := JSONFieldBodyMatcher("status.ok", true)
+	assert.NoError(t, matcher(body))
+
+	matcher = JSONFieldBodyMatcher("status.ok", false)
+	assert.Error(t, matcher(body))
+
+	matcher = JSONFieldBodyMatcher("status.missing", true)
+	assert.Error(t, matcher(body))
+
+	matcher = JSONFieldBodyMatcher("status.ok", true)
+	assert.Error(t, matcher([]byte("not json")))
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright 2022 Michael Graff.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License")
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+type tcpChecker struct {
+	addr    string
+	timeout time.Duration
+}
+
+// TCPChecker returns a Checker that succeeds if a TCP connection to addr
+// can be established (and immediately closed) within timeout.
+func (h *Health) TCPChecker(addr string, timeout time.Duration) Checker {
+	return &tcpChecker{addr: addr, timeout: timeout}
+}
+
+func (tc *tcpChecker) Check(ctx context.Context) error {
+	timeout := tc.timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining < timeout {
+			timeout = remaining
+		}
+	}
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", tc.addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+type dnsChecker struct {
+	host string
+}
+
+// DNSChecker returns a Checker that succeeds if host resolves to at
+// least one A or AAAA record.
+func (h *Health) DNSChecker(host string) Checker {
+	return &dnsChecker{host: host}
+}
+
+func (dc *dnsChecker) Check(ctx context.Context) error {
+	var resolver net.Resolver
+	addrs, err := resolver.LookupHost(ctx, dc.host)
+	if err != nil {
+		return err
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("no addresses found for %s", dc.host)
+	}
+	return nil
+}
+
+type fileChecker struct {
+	path string
+}
+
+// FileChecker returns a Checker that succeeds if path exists and can be
+// opened for reading.
+func (h *Health) FileChecker(path string) Checker {
+	return &fileChecker{path: path}
+}
+
+// Check opens and immediately closes fc.path in a goroutine so a path on a
+// wedged mount can't outlive ctx, even though os.Open itself has no
+// cancellation support.
+func (fc *fileChecker) Check(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		f, err := os.Open(fc.path)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		errCh <- f.Close()
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errCh:
+		return err
+	}
+}
+
+type diskSpaceChecker struct {
+	path         string
+	minFreeBytes uint64
+}
+
+// DiskSpaceChecker returns a Checker that succeeds if the filesystem
+// containing path reports at least minFreeBytes available.  Its Check
+// implementation is platform-specific; see checkers_unix.go and
+// checkers_other.go.
+func (h *Health) DiskSpaceChecker(path string, minFreeBytes uint64) Checker {
+	return &diskSpaceChecker{path: path, minFreeBytes: minFreeBytes}
+}
+
+type jsonRPCRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int    `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type jsonRPCResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type jsonRPCChecker struct {
+	url        string
+	method     string
+	expect     func(json.RawMessage) error
+	httpClient *http.Client
+}
+
+// JSONRPCChecker returns a Checker that POSTs a JSON-RPC 2.0 request for
+// method to url and passes the result field to expect, such as probing
+// an execution-layer endpoint for a recent block number.  A nil expect
+// only checks that the call did not return a JSON-RPC error.
+func (h *Health) JSONRPCChecker(url, method string, expect func(json.RawMessage) error) Checker {
+	return &jsonRPCChecker{url: url, method: method, expect: expect, httpClient: h.httpClient}
+}
+
+func (jc *jsonRPCChecker) Check(ctx context.Context) error {
+	reqBody, err := json.Marshal(jsonRPCRequest{JSONRPC: "2.0", ID: 1, Method: jc.method, Params: []any{}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, jc.url, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("content-type", "application/json")
+	resp, err := jc.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("HTTP status code %d returned", resp.StatusCode)
+	}
+	var rpcResp jsonRPCResponse
+	if err := json.Unmarshal(data, &rpcResp); err != nil {
+		return fmt.Errorf("decoding JSON-RPC response: %w", err)
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("JSON-RPC error %d: %s", rpcResp.Error.Code, rpcResp.Error.Message)
+	}
+	if jc.expect == nil {
+		return nil
+	}
+	return jc.expect(rpcResp.Result)
+}
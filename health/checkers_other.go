@@ -0,0 +1,31 @@
+//go:build !unix
+
+/*
+ * Copyright 2022 Michael Graff.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License")
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+)
+
+// Check always fails: disk space checks rely on syscall.Statfs, which is
+// not available on this platform.
+func (dc *diskSpaceChecker) Check(ctx context.Context) error {
+	return fmt.Errorf("disk space checks are not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright 2022 Michael Graff.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License")
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TCPChecker(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	h := MakeHealth()
+	assert.NoError(t, h.TCPChecker(ln.Addr().String(), time.Second).Check(context.Background()))
+	assert.Error(t, h.TCPChecker("127.0.0.1:0", time.Second).Check(context.Background()))
+}
+
+func Test_DNSChecker(t *testing.T) {
+	h := MakeHealth()
+	assert.NoError(t, h.DNSChecker("localhost").Check(context.Background()))
+	assert.Error(t, h.DNSChecker("this-host-should-not-resolve.invalid").Check(context.Background()))
+}
+
+func Test_JSONRPCChecker(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	defer srv.Close()
+
+	h := MakeHealth()
+	checker := h.JSONRPCChecker(srv.URL, "eth_blockNumber", nil)
+	assert.NoError(t, checker.Check(context.Background()))
+}
+
+func Test_JSONRPCChecker_rpcError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}`))
+	}))
+	defer srv.Close()
+
+	h := MakeHealth()
+	checker := h.JSONRPCChecker(srv.URL, "bogus", nil)
+	assert.Error(t, checker.Check(context.Background()))
+}
+
+func Test_FileChecker(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "gohealthcheck-filechecker")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	h := MakeHealth()
+	assert.NoError(t, h.FileChecker(f.Name()).Check(context.Background()))
+	assert.Error(t, h.FileChecker(f.Name()+"-missing").Check(context.Background()))
+}
+
+func Test_FileChecker_respectsCanceledContext(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "gohealthcheck-filechecker")
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	h := MakeHealth()
+	err = h.FileChecker(f.Name()).Check(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func Test_DiskSpaceChecker(t *testing.T) {
+	h := MakeHealth()
+
+	err := h.DiskSpaceChecker(t.TempDir(), 1).Check(context.Background())
+	if err != nil {
+		// Platforms without syscall.Statfs (see checkers_other.go) always
+		// report an error; anything else is an unexpected failure.
+		assert.Contains(t, err.Error(), "not supported")
+		return
+	}
+
+	err = h.DiskSpaceChecker(t.TempDir(), 1<<62).Check(context.Background())
+	assert.Error(t, err, "an implausibly large minFreeBytes should never be satisfied")
+}
@@ -0,0 +1,54 @@
+//go:build unix
+
+/*
+ * Copyright 2022 Michael Graff.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License")
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+)
+
+// Check runs syscall.Statfs in a goroutine so a path on a wedged or
+// unresponsive mount can't outlive ctx.
+func (dc *diskSpaceChecker) Check(ctx context.Context) error {
+	type statResult struct {
+		stat syscall.Statfs_t
+		err  error
+	}
+	resCh := make(chan statResult, 1)
+	go func() {
+		var stat syscall.Statfs_t
+		err := syscall.Statfs(dc.path, &stat)
+		resCh <- statResult{stat: stat, err: err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case res := <-resCh:
+		if res.err != nil {
+			return res.err
+		}
+		available := uint64(res.stat.Bavail) * uint64(res.stat.Bsize)
+		if available < dc.minFreeBytes {
+			return fmt.Errorf("only %d bytes free on %s, want at least %d", available, dc.path, dc.minFreeBytes)
+		}
+		return nil
+	}
+}
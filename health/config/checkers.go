@@ -0,0 +1,68 @@
+/*
+ * Copyright 2022 Michael Graff.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License")
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/skandragon/gohealthcheck/health"
+)
+
+// intSeconds converts a plain integer number of seconds, as used in
+// CheckSpec, into a time.Duration.  Zero stays zero, meaning "use the
+// default".
+func intSeconds(seconds int) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func httpFactory(h *health.Health, spec CheckSpec) (health.Checker, error) {
+	if spec.URL == "" {
+		return nil, fmt.Errorf("missing url")
+	}
+	if len(spec.Headers) == 0 {
+		return h.HTTPChecker(spec.URL), nil
+	}
+	return h.HTTPCheckerWithOptions(health.HTTPCheckerOptions{
+		URL:     spec.URL,
+		Headers: spec.Headers,
+	}), nil
+}
+
+func tcpFactory(h *health.Health, spec CheckSpec) (health.Checker, error) {
+	if spec.URL == "" {
+		return nil, fmt.Errorf("missing url (host:port)")
+	}
+	return h.TCPChecker(spec.URL, intSeconds(spec.Timeout)), nil
+}
+
+func dnsFactory(h *health.Health, spec CheckSpec) (health.Checker, error) {
+	if spec.URL == "" {
+		return nil, fmt.Errorf("missing url (hostname)")
+	}
+	return h.DNSChecker(spec.URL), nil
+}
+
+func fileFactory(h *health.Health, spec CheckSpec) (health.Checker, error) {
+	if spec.URL == "" {
+		return nil, fmt.Errorf("missing url (path)")
+	}
+	return h.FileChecker(spec.URL), nil
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright 2022 Michael Graff.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License")
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package config builds a *health.Health from a declarative list of
+// check definitions, so ops teams can reconfigure checks without
+// recompiling.
+package config
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/skandragon/gohealthcheck/health"
+)
+
+// CheckSpec is the declarative definition of a single check, as parsed
+// from YAML or JSON.
+type CheckSpec struct {
+	Name        string            `yaml:"name" json:"name"`
+	Type        string            `yaml:"type" json:"type"`
+	URL         string            `yaml:"url,omitempty" json:"url,omitempty"`
+	Interval    int               `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Timeout     int               `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Threshold   int               `yaml:"threshold,omitempty" json:"threshold,omitempty"`
+	ObserveOnly bool              `yaml:"observeOnly,omitempty" json:"observeOnly,omitempty"`
+	Headers     map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+}
+
+// document is the top-level shape of a check configuration file.
+type document struct {
+	Checks []CheckSpec `yaml:"checks"`
+}
+
+// CheckerFactory builds a health.Checker from a check's declarative spec.
+type CheckerFactory func(h *health.Health, spec CheckSpec) (health.Checker, error)
+
+var registryMu sync.RWMutex
+var registry = map[string]CheckerFactory{
+	"http": httpFactory,
+	"tcp":  tcpFactory,
+	"dns":  dnsFactory,
+	"file": fileFactory,
+}
+
+// RegisterCheckerType registers factory under name, so LoadFromYAML can
+// build checks of that type.  This lets downstream apps plug in custom
+// checkers by name without importing them into this module.  Safe to call
+// concurrently with LoadFromYAML or other RegisterCheckerType calls.
+func RegisterCheckerType(name string, factory CheckerFactory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+func lookupCheckerType(name string) (CheckerFactory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	factory, ok := registry[name]
+	return factory, ok
+}
+
+// LoadFromYAML parses a declarative check configuration of the form
+//
+//	checks:
+//	  - name: my-api
+//	    type: http
+//	    url: https://example.com/healthz
+//	    interval: 10
+//	    threshold: 3
+//
+// and returns a *health.Health with each check registered via
+// AddCheckWithOptions, ready to pass to RunCheckers.
+func LoadFromYAML(r io.Reader) (*health.Health, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc document
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing health config: %w", err)
+	}
+
+	h := health.MakeHealth()
+	for _, spec := range doc.Checks {
+		factory, ok := lookupCheckerType(spec.Type)
+		if !ok {
+			return nil, fmt.Errorf("check %q: unknown type %q", spec.Name, spec.Type)
+		}
+		checker, err := factory(h, spec)
+		if err != nil {
+			return nil, fmt.Errorf("check %q: %w", spec.Name, err)
+		}
+		h.AddCheckWithOptions(spec.Name, spec.ObserveOnly, checker, health.CheckOptions{
+			Interval:         intSeconds(spec.Interval),
+			Timeout:          intSeconds(spec.Timeout),
+			FailureThreshold: spec.Threshold,
+			SuccessThreshold: spec.Threshold,
+		})
+	}
+	return h, nil
+}
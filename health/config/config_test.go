@@ -0,0 +1,101 @@
+/*
+ * Copyright 2022 Michael Graff.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License")
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/skandragon/gohealthcheck/health"
+)
+
+func Test_LoadFromYAML_buildsChecks(t *testing.T) {
+	yamlDoc := `
+checks:
+  - name: homepage
+    type: http
+    url: https://example.com/
+    interval: 5
+    threshold: 2
+  - name: dns
+    type: dns
+    url: example.com
+    observeOnly: true
+`
+	h, err := LoadFromYAML(strings.NewReader(yamlDoc))
+	assert.NoError(t, err)
+	assert.Len(t, h.Checks, 2)
+	assert.Equal(t, "homepage", h.Checks[0].Service)
+	assert.Equal(t, "dns", h.Checks[1].Service)
+	assert.True(t, h.Checks[1].ObserveOnly)
+}
+
+func Test_LoadFromYAML_unknownType(t *testing.T) {
+	yamlDoc := `
+checks:
+  - name: mystery
+    type: carrier-pigeon
+`
+	_, err := LoadFromYAML(strings.NewReader(yamlDoc))
+	assert.Error(t, err)
+}
+
+func Test_RegisterCheckerType_custom(t *testing.T) {
+	RegisterCheckerType("always-ok", func(h *health.Health, spec CheckSpec) (health.Checker, error) {
+		return health.CheckFunc(func(ctx context.Context) error { return nil }), nil
+	})
+
+	yamlDoc := `
+checks:
+  - name: custom
+    type: always-ok
+`
+	h, err := LoadFromYAML(strings.NewReader(yamlDoc))
+	assert.NoError(t, err)
+	assert.Len(t, h.Checks, 1)
+}
+
+// Test_RegisterCheckerType_concurrentWithLoadFromYAML exercises
+// RegisterCheckerType and LoadFromYAML from separate goroutines to catch
+// data races on the package-level registry; run with -race to verify.
+func Test_RegisterCheckerType_concurrentWithLoadFromYAML(t *testing.T) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			RegisterCheckerType(fmt.Sprintf("custom-%d", i), func(h *health.Health, spec CheckSpec) (health.Checker, error) {
+				return health.CheckFunc(func(ctx context.Context) error { return nil }), nil
+			})
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_, _ = LoadFromYAML(strings.NewReader("checks:\n  - name: homepage\n    type: http\n    url: https://example.com/\n"))
+		}
+	}()
+
+	wg.Wait()
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2022 Michael Graff.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License")
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+// LivenessHandler returns 200 as long as the RunCheckers loop itself is
+// still iterating, regardless of individual check results.  Wire this up
+// as the Kubernetes liveness probe so a single wedged dependency doesn't
+// trigger a pod restart; use ReadinessHandler to gate traffic on that
+// instead.  Checks run inside that loop, so a single Check() that runs
+// longer than the liveness timeout (for example one with no Timeout that
+// never returns) does make this handler report not alive.
+func (h *Health) LivenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.Lock()
+		alive := h.lastLoopAt.IsZero() || time.Since(h.lastLoopAt) < h.livenessTimeout
+		statusCode := h.failureStatusCode
+		h.Unlock()
+
+		w.Header().Set("content-type", "application/json")
+		data, err := json.Marshal(struct {
+			Alive bool `json:"alive"`
+		}{alive})
+		if err != nil {
+			w.WriteHeader(500)
+			log.Printf("Healthcheck LivenessHandler: %v", err)
+			return
+		}
+		if alive {
+			w.WriteHeader(200)
+		} else {
+			w.WriteHeader(statusCode)
+		}
+		if _, err := w.Write(data); err != nil {
+			log.Printf("when writing body: %v", err)
+		}
+	}
+}
+
+// ReadinessHandler returns 200 if all Readiness-classified, non-observe-only
+// checks pass, or the configured failure status code otherwise.  Liveness
+// and Startup classified checks do not affect readiness.
+func (h *Health) ReadinessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.Lock()
+		healthy := true
+		for _, c := range h.Checks {
+			if c.ObserveOnly || c.Classification != Readiness {
+				continue
+			}
+			healthy = healthy && c.Healthy
+		}
+		data, err := json.Marshal(h)
+		statusCode := h.failureStatusCode
+		h.Unlock()
+
+		w.Header().Set("content-type", "application/json")
+		if err != nil {
+			w.WriteHeader(500)
+			log.Printf("Healthcheck ReadinessHandler: %v", err)
+			return
+		}
+		if healthy {
+			w.WriteHeader(200)
+		} else {
+			w.WriteHeader(statusCode)
+		}
+		if _, err := w.Write(data); err != nil {
+			log.Printf("when writing body: %v", err)
+		}
+	}
+}
+
+// checkDetail is the per-check payload returned by DetailsHandler.
+type checkDetail struct {
+	Service        string         `json:"service"`
+	Healthy        bool           `json:"healthy"`
+	ObserveOnly    bool           `json:"observeOnly,omitempty"`
+	Classification Classification `json:"classification,omitempty"`
+	LastChecked    uint64         `json:"lastChecked,omitempty"`
+	History        []CheckResult  `json:"history,omitempty"`
+}
+
+// DetailsHandler returns the bounded history of results (timestamp,
+// duration, error) for every check, so operators can inspect flap rates
+// and slow probes beyond the current boolean exposed by HTTPHandler.
+func (h *Health) DetailsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.Lock()
+		details := make([]checkDetail, 0, len(h.Checks))
+		for _, c := range h.Checks {
+			details = append(details, checkDetail{
+				Service:        c.Service,
+				Healthy:        c.Healthy,
+				ObserveOnly:    c.ObserveOnly,
+				Classification: c.Classification,
+				LastChecked:    c.LastChecked,
+				History:        orderedHistory(c),
+			})
+		}
+		h.Unlock()
+
+		w.Header().Set("content-type", "application/json")
+		data, err := json.Marshal(details)
+		if err != nil {
+			w.WriteHeader(500)
+			log.Printf("Healthcheck DetailsHandler: %v", err)
+			return
+		}
+		w.WriteHeader(200)
+		if _, err := w.Write(data); err != nil {
+			log.Printf("when writing body: %v", err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright 2022 Michael Graff.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License")
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ReadinessHandler_ignoresLivenessChecks(t *testing.T) {
+	h := MakeHealth()
+	h.AddCheckWithOptions("db", false, &successChecker, CheckOptions{Classification: Readiness})
+	h.AddCheckWithOptions("wedged-process", false, &failChecker, CheckOptions{Classification: Liveness})
+	h.Checks[0].Healthy = true
+	h.Checks[1].Healthy = false
+
+	rr := httptest.NewRecorder()
+	h.ReadinessHandler()(rr, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+	assert.Equal(t, http.StatusOK, rr.Code, "an unhealthy Liveness check should not fail readiness")
+}
+
+func Test_ReadinessHandler_failsOnUnhealthyReadinessCheck(t *testing.T) {
+	h := MakeHealth()
+	h.AddCheckWithOptions("db", false, &failChecker, CheckOptions{Classification: Readiness})
+	h.Checks[0].Healthy = false
+
+	rr := httptest.NewRecorder()
+	h.ReadinessHandler()(rr, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+}
+
+func Test_LivenessHandler_reflectsLoopActivity(t *testing.T) {
+	h := MakeHealth().WithLivenessTimeout(15 * time.Millisecond)
+
+	rr := httptest.NewRecorder()
+	h.LivenessHandler()(rr, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	assert.Equal(t, http.StatusOK, rr.Code, "a loop that has never run is not yet considered wedged")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	h.RunCheckers(ctx, 0)
+	time.Sleep(100 * time.Millisecond)
+
+	rr = httptest.NewRecorder()
+	h.LivenessHandler()(rr, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code, "loop stopped iterating past livenessTimeout ago")
+}
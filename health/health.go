@@ -17,45 +17,128 @@
 package health
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Checker is an interface that defines a Check() function.  This Check()
 // will be called periorically from a goproc, so if any external resources
 // need to be locked, it must handle this correctly.
+// The provided context is canceled when the check's configured timeout
+// elapses or when the caller is shutting down, and should be passed
+// through to anything that supports cancellation.
 // It should return an error if the check fails, where the contents of the
 // error will be included in the health indicator's JSON.
 // Return nil to indicate success.
 type Checker interface {
-	Check() error
+	Check(ctx context.Context) error
 }
 
-type httpChecker struct {
-	url        string
-	httpClient *http.Client
+// CheckFunc adapts a plain function to the Checker interface, so callers
+// can register ad-hoc checks without declaring a dedicated type.
+type CheckFunc func(ctx context.Context) error
+
+// Check implements the Checker interface.
+func (f CheckFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Classification describes what kind of Kubernetes-style probe a check
+// should be counted towards.
+type Classification string
+
+const (
+	// Readiness checks gate whether the service should receive traffic.
+	// This is the default classification.
+	Readiness Classification = "readiness"
+	// Liveness checks are excluded from ReadinessHandler, on the theory
+	// that they test something more fundamental than whether the
+	// service should receive traffic.  LivenessHandler does not
+	// currently consult individual checks at all — it only tracks
+	// whether RunCheckers' loop is still iterating — so tagging a
+	// check Liveness does not by itself make it restart the process;
+	// wire that up yourself if you need it (e.g. call os.Exit from the
+	// Checker on a condition you consider unrecoverable).
+	Liveness Classification = "liveness"
+	// Startup checks gate whether the service has finished starting up.
+	Startup Classification = "startup"
+)
+
+// defaultHistorySize is the number of past results retained per check
+// for the details endpoint.
+const defaultHistorySize = 20
+
+// CheckResult is one historical result recorded for a check, exposed via
+// DetailsHandler.
+type CheckResult struct {
+	Timestamp  uint64 `json:"timestamp"`
+	DurationMS int64  `json:"durationMs"`
+	Error      string `json:"error,omitempty"`
+}
+
+// CheckOptions configures the optional scheduling and hysteresis behavior
+// for a check registered via AddCheckWithOptions.
+type CheckOptions struct {
+	// Interval overrides the default frequency passed to RunCheckers for
+	// this check only.  Zero means "use the default".
+	Interval time.Duration
+	// Timeout bounds how long a single Check() call may run before its
+	// context is canceled.  Zero means no per-check timeout.
+	Timeout time.Duration
+	// FailureThreshold is the number of consecutive failures required
+	// before the check is reported unhealthy.  Values less than 1 are
+	// treated as 1, so a single failure flips the check immediately.
+	FailureThreshold int
+	// SuccessThreshold is the number of consecutive successes required
+	// before the check is reported healthy again.  Values less than 1
+	// are treated as 1.
+	SuccessThreshold int
+	// Classification determines which probe handler this check is
+	// counted towards.  The zero value is treated as Readiness.
+	Classification Classification
 }
 
 type healthIndicator struct {
-	Service     string `json:"service,omitempty"`
-	Healthy     bool   `json:"healthy,omitempty"`
-	Message     string `json:"message,omitempty"`
-	ObserveOnly bool   `json:"observeOnly,omitempty"`
-	LastChecked uint64 `json:"lastChecked,omitempty"`
+	Service        string         `json:"service,omitempty"`
+	Healthy        bool           `json:"healthy,omitempty"`
+	Message        string         `json:"message,omitempty"`
+	ObserveOnly    bool           `json:"observeOnly,omitempty"`
+	LastChecked    uint64         `json:"lastChecked,omitempty"`
+	Classification Classification `json:"classification,omitempty"`
 
 	checker Checker
+
+	Interval         time.Duration `json:"-"`
+	Timeout          time.Duration `json:"-"`
+	FailureThreshold int           `json:"-"`
+	SuccessThreshold int           `json:"-"`
+
+	nextRun              time.Time
+	consecutiveFailures  int
+	consecutiveSuccesses int
+
+	history     []CheckResult
+	historyNext int
 }
 
 // Health holds state for the current health checker.
 type Health struct {
 	sync.Mutex
-	run        bool
-	httpClient *http.Client
+	httpClient        *http.Client
+	failureStatusCode int
+	livenessTimeout   time.Duration
+	lastLoopAt        time.Time
+
+	metricsUp       *prometheus.GaugeVec
+	metricsFailures *prometheus.CounterVec
+	metricsDuration *prometheus.HistogramVec
 
 	Healthy bool              `json:"healthy,omitempty"`
 	Checks  []healthIndicator `json:"checks,omitempty"`
@@ -64,7 +147,9 @@ type Health struct {
 // MakeHealth will return a new, empty health checker.
 func MakeHealth() *Health {
 	return &Health{
-		httpClient: http.DefaultClient,
+		httpClient:        http.DefaultClient,
+		failureStatusCode: http.StatusServiceUnavailable,
+		livenessTimeout:   30 * time.Second,
 	}
 }
 
@@ -73,23 +158,75 @@ func (h *Health) WithHTTPClient(client *http.Client) *Health {
 	return h
 }
 
+// WithFailureStatusCode sets the HTTP status code returned by HTTPHandler
+// and ReadinessHandler when unhealthy.  Defaults to 503, which is what
+// most orchestrators expect from a failing readiness probe.
+func (h *Health) WithFailureStatusCode(code int) *Health {
+	h.failureStatusCode = code
+	return h
+}
+
+// WithLivenessTimeout sets how long RunCheckers' loop may go between
+// iterations before LivenessHandler reports the process as not alive.
+func (h *Health) WithLivenessTimeout(d time.Duration) *Health {
+	h.livenessTimeout = d
+	return h
+}
+
 func removeChecker(s []healthIndicator, i int) []healthIndicator {
 	s[i] = s[len(s)-1]
 	return s[:len(s)-1]
 }
 
 // AddCheck adds a new checker.  For HTTP checkers, use health.HTTPChecker(url).
+// The check is reported unhealthy or healthy after a single failure or
+// success.  Use AddCheckWithOptions to configure hysteresis or a custom
+// interval.
 func (h *Health) AddCheck(service string, observeOnly bool, checker Checker) {
+	h.AddCheckWithOptions(service, observeOnly, checker, CheckOptions{})
+}
+
+// AddCheckWithOptions adds a new checker, like AddCheck, but allows
+// configuring its interval, per-check timeout, and the number of
+// consecutive failures or successes required before its reported status
+// changes.  This avoids flapping between healthy and unhealthy on
+// transient blips.
+func (h *Health) AddCheckWithOptions(service string, observeOnly bool, checker Checker, opts CheckOptions) {
+	if opts.FailureThreshold < 1 {
+		opts.FailureThreshold = 1
+	}
+	if opts.SuccessThreshold < 1 {
+		opts.SuccessThreshold = 1
+	}
+	if opts.Classification == "" {
+		opts.Classification = Readiness
+	}
+
 	h.Lock()
 	defer h.Unlock()
-	for _, c := range h.Checks {
+	for i, c := range h.Checks {
 		if c.Service == service {
-			c.checker = checker
-			c.ObserveOnly = observeOnly
+			h.Checks[i].checker = checker
+			h.Checks[i].ObserveOnly = observeOnly
+			h.Checks[i].Interval = opts.Interval
+			h.Checks[i].Timeout = opts.Timeout
+			h.Checks[i].FailureThreshold = opts.FailureThreshold
+			h.Checks[i].SuccessThreshold = opts.SuccessThreshold
+			h.Checks[i].Classification = opts.Classification
 			return
 		}
 	}
-	h.Checks = append(h.Checks, healthIndicator{service, true, "", observeOnly, 0, checker})
+	h.Checks = append(h.Checks, healthIndicator{
+		Service:          service,
+		Healthy:          true,
+		ObserveOnly:      observeOnly,
+		checker:          checker,
+		Interval:         opts.Interval,
+		Timeout:          opts.Timeout,
+		FailureThreshold: opts.FailureThreshold,
+		SuccessThreshold: opts.SuccessThreshold,
+		Classification:   opts.Classification,
+	})
 }
 
 // RemoveCheck removes a checker.  This will eventually converge in the output.
@@ -104,79 +241,169 @@ func (h *Health) RemoveCheck(service string) {
 	}
 }
 
-// This is called while (h) is unlocked.
-func (h *Health) runChecker(checker *healthIndicator) {
-	err := checker.checker.Check()
-	if err == nil {
-		checker.Healthy = true
-		checker.Message = "OK"
-	} else {
-		checker.Healthy = false
-		checker.Message = fmt.Sprintf("%s ERROR %v", checker.Service, err)
-	}
-	checker.LastChecked = uint64(time.Now().UnixMilli())
+// dueCheck is a snapshot of the inputs runChecker needs to run a single
+// check, taken while h is locked so runChecker never holds a pointer into
+// h.Checks across the unlocked Check() call below.
+type dueCheck struct {
+	service string
+	checker Checker
+	timeout time.Duration
 }
 
-// RunCheckers runs all the health checks, one every frequency/count seconds.
-func (h *Health) RunCheckers(frequency int) {
-	nextIndex := 0
-	firstPass := true // used to ensure we scan fast on first start
+// runChecker runs a single due check and records its result.  The check
+// itself is run without holding h's lock, so a slow or wedged Check()
+// cannot block AddCheckWithOptions or RemoveCheck; the
+// result is then written back under lock by looking the check back up by
+// service name, since h.Checks may have been reslices or reordered while
+// this check was running.
+func (h *Health) runChecker(ctx context.Context, dc dueCheck) {
+	checkCtx := ctx
+	if dc.timeout > 0 {
+		var cancel context.CancelFunc
+		checkCtx, cancel = context.WithTimeout(ctx, dc.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := dc.checker.Check(checkCtx)
+	duration := time.Since(start)
+	now := time.Now()
+
+	result := CheckResult{Timestamp: uint64(now.UnixMilli()), DurationMS: duration.Milliseconds()}
+	if err != nil {
+		result.Error = err.Error()
+	}
 
 	h.Lock()
-	h.run = true
-	count := len(h.Checks) + 1 // ensure we are at least 1
-	h.Unlock()
+	defer h.Unlock()
 
-	for {
-		// ensure we sleep while not locked.
-		sleepDuration := time.Duration(frequency) * time.Second / time.Duration(count)
-		if firstPass {
-			sleepDuration = time.Duration(10) * time.Millisecond
+	idx := -1
+	for i := range h.Checks {
+		if h.Checks[i].Service == dc.service {
+			idx = i
+			break
 		}
-		time.Sleep(sleepDuration)
+	}
+	if idx < 0 {
+		// Removed while the check was running; nothing left to update.
+		return
+	}
+	checker := &h.Checks[idx]
 
-		// locked while manitulating things and calling healthcheck
-		h.Lock()
-		count = len(h.Checks) + 1
-		if !h.run {
-			h.Unlock()
-			return
-		}
-		if nextIndex >= len(h.Checks) {
-			nextIndex = 0
-			firstPass = false
+	if err == nil {
+		checker.consecutiveFailures = 0
+		checker.consecutiveSuccesses++
+		if checker.consecutiveSuccesses >= checker.SuccessThreshold {
+			checker.Healthy = true
+			checker.Message = "OK"
 		}
-		if len(h.Checks) > 0 {
-			h.Unlock()
-			h.runChecker(&h.Checks[nextIndex])
-			h.Lock()
+	} else {
+		checker.consecutiveSuccesses = 0
+		checker.consecutiveFailures++
+		if checker.consecutiveFailures >= checker.FailureThreshold {
+			checker.Healthy = false
+			checker.Message = fmt.Sprintf("%s ERROR %v", checker.Service, err)
 		}
-		nextIndex++
+	}
+	pushHistory(checker, result)
+	checker.LastChecked = uint64(now.UnixMilli())
 
-		// Now, check all statuses and compute the global status
-		h.Healthy = true
-		for _, c := range h.Checks {
-			if c.ObserveOnly {
-				continue
-			}
-			h.Healthy = h.Healthy && c.Healthy
+	if h.metricsDuration != nil {
+		h.metricsDuration.WithLabelValues(checker.Service).Observe(duration.Seconds())
+	}
+	if err != nil && h.metricsFailures != nil {
+		h.metricsFailures.WithLabelValues(checker.Service).Inc()
+	}
+	if h.metricsUp != nil {
+		up := 0.0
+		if checker.Healthy {
+			up = 1
 		}
-		h.Unlock()
+		h.metricsUp.WithLabelValues(checker.Service).Set(up)
 	}
 }
 
-// StopCheckers will stop running RunCheckers()
-func (h *Health) StopCheckers() {
-	h.Lock()
-	defer h.Unlock()
-	h.run = false
+// pushHistory records result into checker's bounded ring buffer of past
+// results, overwriting the oldest entry once defaultHistorySize is
+// reached.
+func pushHistory(checker *healthIndicator, result CheckResult) {
+	if cap(checker.history) < defaultHistorySize {
+		checker.history = make([]CheckResult, 0, defaultHistorySize)
+	}
+	if len(checker.history) < defaultHistorySize {
+		checker.history = append(checker.history, result)
+		return
+	}
+	checker.history[checker.historyNext] = result
+	checker.historyNext = (checker.historyNext + 1) % defaultHistorySize
 }
 
-// HTTPChecker adds returns a HealthChecker that will
-// poll the provided URL, and use any http error
-// or status code to indicate success or failure.
-func (h *Health) HTTPChecker(url string) Checker {
-	return &httpChecker{url: url, httpClient: h.httpClient}
+// orderedHistory returns checker's history in oldest-to-newest order.
+func orderedHistory(checker healthIndicator) []CheckResult {
+	if len(checker.history) < cap(checker.history) {
+		return checker.history
+	}
+	ordered := make([]CheckResult, 0, len(checker.history))
+	ordered = append(ordered, checker.history[checker.historyNext:]...)
+	ordered = append(ordered, checker.history[:checker.historyNext]...)
+	return ordered
+}
+
+// RunCheckers runs all the health checks, each on its own Interval (or
+// frequency seconds, if the check did not set one), until ctx is
+// canceled.  Each check's Check() is given a context derived from ctx,
+// bounded by its configured Timeout if any.  Due checks run one after
+// another, so a slow check delays the checks after it by up to its
+// Timeout, or for as long as its Check() runs if it has no Timeout.
+func (h *Health) RunCheckers(ctx context.Context, frequency int) {
+	defaultInterval := time.Duration(frequency) * time.Second
+	if defaultInterval <= 0 {
+		defaultInterval = time.Second
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			h.Lock()
+			h.lastLoopAt = now
+			var due []dueCheck
+			for i := range h.Checks {
+				c := &h.Checks[i]
+				interval := c.Interval
+				if interval <= 0 {
+					interval = defaultInterval
+				}
+				if c.nextRun.IsZero() || !now.Before(c.nextRun) {
+					due = append(due, dueCheck{service: c.Service, checker: c.checker, timeout: c.Timeout})
+					c.nextRun = now.Add(interval)
+				}
+			}
+			h.Unlock()
+
+			for _, dc := range due {
+				h.runChecker(ctx, dc)
+			}
+
+			if len(due) == 0 {
+				continue
+			}
+
+			h.Lock()
+			h.Healthy = true
+			for _, c := range h.Checks {
+				if c.ObserveOnly {
+					continue
+				}
+				h.Healthy = h.Healthy && c.Healthy
+			}
+			h.Unlock()
+		}
+	}
 }
 
 // HTTPHandler which returns 200 if all critical checks pass, or 500 if not.
@@ -195,7 +422,7 @@ func (h *Health) HTTPHandler() http.HandlerFunc {
 		if healthy {
 			w.WriteHeader(200)
 		} else {
-			w.WriteHeader(418)
+			w.WriteHeader(h.failureStatusCode)
 		}
 		written, err := w.Write(data)
 		if err != nil {
@@ -206,23 +433,3 @@ func (h *Health) HTTPHandler() http.HandlerFunc {
 		}
 	}
 }
-
-// Check implements the HealthChecker interface, using a HTTP fetch.
-// Any status code between 200 and 399 indicates success, any other
-// indicates a failure.
-func (hc *httpChecker) Check() error {
-	client := hc.httpClient
-	resp, err := client.Get(hc.url)
-	if err != nil {
-		return err
-	}
-	_, err = io.Copy(io.Discard, resp.Body)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode >= 200 && resp.StatusCode < 400 {
-		return nil
-	}
-	return fmt.Errorf("HTTP status code %d returned", resp.StatusCode)
-}
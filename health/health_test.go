@@ -17,8 +17,10 @@
 package health
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -28,7 +30,7 @@ type testChecker struct {
 	err    error
 }
 
-func (tc *testChecker) Check() error {
+func (tc *testChecker) Check(ctx context.Context) error {
 	tc.called = true
 	return tc.err
 }
@@ -41,13 +43,105 @@ func resetCheckers() {
 	successChecker.called = false
 }
 
+func Test_CheckFunc(t *testing.T) {
+	called := false
+	var f CheckFunc = func(ctx context.Context) error {
+		called = true
+		return nil
+	}
+	assert.NoError(t, f.Check(context.Background()))
+	assert.True(t, called)
+}
+
 func Test_Health_callsChecker(t *testing.T) {
 	h := MakeHealth()
 	resetCheckers()
 
 	h.AddCheck("test", false, &successChecker)
-	h.runChecker(&h.Checks[0])
+	h.runChecker(context.Background(), dueCheck{service: "test", checker: h.Checks[0].checker})
 	assert.True(t, successChecker.called)
 	assert.True(t, h.Checks[0].Healthy)
 	assert.Equal(t, "OK", h.Checks[0].Message)
 }
+
+func Test_Health_failureThreshold_transitions(t *testing.T) {
+	tests := []struct {
+		name             string
+		failureThreshold int
+		failures         int
+		wantHealthy      bool
+	}{
+		{"below threshold stays healthy", 3, 2, true},
+		{"at threshold flips unhealthy", 3, 3, false},
+		{"default threshold of 1 flips on first failure", 1, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := MakeHealth()
+			checker := &testChecker{err: fmt.Errorf("boom")}
+			h.AddCheckWithOptions("svc", false, checker, CheckOptions{FailureThreshold: tt.failureThreshold})
+			dc := dueCheck{service: "svc", checker: checker}
+			for i := 0; i < tt.failures; i++ {
+				h.runChecker(context.Background(), dc)
+			}
+			assert.Equal(t, tt.wantHealthy, h.Checks[0].Healthy)
+		})
+	}
+}
+
+func Test_Health_successThreshold_recovers(t *testing.T) {
+	h := MakeHealth()
+	checker := &testChecker{err: fmt.Errorf("boom")}
+	h.AddCheckWithOptions("svc", false, checker, CheckOptions{FailureThreshold: 1, SuccessThreshold: 2})
+	dc := dueCheck{service: "svc", checker: checker}
+
+	h.runChecker(context.Background(), dc)
+	assert.False(t, h.Checks[0].Healthy)
+
+	checker.err = nil
+	h.runChecker(context.Background(), dc)
+	assert.False(t, h.Checks[0].Healthy, "one success should not clear a SuccessThreshold of 2")
+
+	h.runChecker(context.Background(), dc)
+	assert.True(t, h.Checks[0].Healthy)
+	assert.Equal(t, "OK", h.Checks[0].Message)
+}
+
+func Test_pushHistory_wraparound(t *testing.T) {
+	checker := &healthIndicator{}
+	for i := 0; i < defaultHistorySize+5; i++ {
+		pushHistory(checker, CheckResult{Timestamp: uint64(i)})
+	}
+
+	ordered := orderedHistory(*checker)
+	assert.Len(t, ordered, defaultHistorySize)
+	// The oldest 5 entries were overwritten, so the
+	// buffer should hold timestamps 5..defaultHistorySize+4 in order.
+	for i, result := range ordered {
+		assert.Equal(t, uint64(i+5), result.Timestamp)
+	}
+}
+
+// Test_RunCheckers_concurrentAddRemove exercises RunCheckers concurrently
+// with AddCheckWithOptions and RemoveCheck to catch data races between the
+// scheduling loop and check registration; run with -race to verify.
+func Test_RunCheckers_concurrentAddRemove(t *testing.T) {
+	h := MakeHealth()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	go h.RunCheckers(ctx, 0)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; ctx.Err() == nil; i++ {
+			service := fmt.Sprintf("svc-%d", i%3)
+			h.AddCheckWithOptions(service, false, &successChecker, CheckOptions{Interval: time.Millisecond})
+			h.RemoveCheck(service)
+		}
+	}()
+
+	<-ctx.Done()
+	<-done
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2022 Michael Graff.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License")
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type httpChecker struct {
+	url        string
+	method     string
+	body       []byte
+	headers    map[string]string
+	minStatus  int
+	maxStatus  int
+	matcher    BodyMatcher
+	httpClient *http.Client
+}
+
+// HTTPCheckerOptions configures a checker built with HTTPCheckerWithOptions.
+type HTTPCheckerOptions struct {
+	// URL is the endpoint to poll.
+	URL string
+	// Method is the HTTP method to use.  Defaults to GET.
+	Method string
+	// Body, if non-nil, is sent as the request body.
+	Body []byte
+	// Headers are set on the outgoing request.
+	Headers map[string]string
+	// MinStatus and MaxStatus bound the accepted status code range,
+	// inclusive.  Default to 200 and 399.
+	MinStatus int
+	MaxStatus int
+	// Matcher, if set, additionally inspects the response body and
+	// fails the check if it does not match.
+	Matcher BodyMatcher
+}
+
+// HTTPChecker adds returns a Checker that will
+// poll the provided URL, and use any http error
+// or status code to indicate success or failure.
+func (h *Health) HTTPChecker(url string) Checker {
+	return &httpChecker{
+		url:        url,
+		method:     http.MethodGet,
+		minStatus:  200,
+		maxStatus:  399,
+		httpClient: h.httpClient,
+	}
+}
+
+// HTTPCheckerWithOptions returns a Checker like HTTPChecker, but
+// allows configuring the request method, body, headers, accepted status
+// range, and a BodyMatcher to additionally validate the response body.
+func (h *Health) HTTPCheckerWithOptions(opts HTTPCheckerOptions) Checker {
+	method := opts.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	minStatus := opts.MinStatus
+	if minStatus == 0 {
+		minStatus = 200
+	}
+	maxStatus := opts.MaxStatus
+	if maxStatus == 0 {
+		maxStatus = 399
+	}
+	return &httpChecker{
+		url:        opts.URL,
+		method:     method,
+		body:       opts.Body,
+		headers:    opts.Headers,
+		minStatus:  minStatus,
+		maxStatus:  maxStatus,
+		matcher:    opts.Matcher,
+		httpClient: h.httpClient,
+	}
+}
+
+// Check implements the Checker interface, using a HTTP fetch.
+// A status code outside [minStatus, maxStatus] indicates a failure, as
+// does a body that fails the configured matcher, if any.
+func (hc *httpChecker) Check(ctx context.Context) error {
+	var bodyReader io.Reader
+	if hc.body != nil {
+		bodyReader = bytes.NewReader(hc.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, hc.method, hc.url, bodyReader)
+	if err != nil {
+		return err
+	}
+	for k, v := range hc.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := hc.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < hc.minStatus || resp.StatusCode > hc.maxStatus {
+		return fmt.Errorf("HTTP status code %d returned", resp.StatusCode)
+	}
+	if hc.matcher != nil {
+		if err := hc.matcher(data); err != nil {
+			return fmt.Errorf("response body check failed: %w", err)
+		}
+	}
+	return nil
+}
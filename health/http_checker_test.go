@@ -0,0 +1,68 @@
+/*
+ * Copyright 2022 Michael Graff.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License")
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HTTPChecker(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	h := MakeHealth()
+	assert.NoError(t, h.HTTPChecker(srv.URL).Check(context.Background()))
+}
+
+func Test_HTTPCheckerWithOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "want", r.Header.Get("X-Probe"))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"status":{"ok":true}}`))
+	}))
+	defer srv.Close()
+
+	h := MakeHealth()
+	checker := h.HTTPCheckerWithOptions(HTTPCheckerOptions{
+		URL:       srv.URL,
+		Method:    http.MethodPost,
+		Headers:   map[string]string{"X-Probe": "want"},
+		MinStatus: 200,
+		MaxStatus: 299,
+		Matcher:   JSONFieldBodyMatcher("status.ok", true),
+	})
+	assert.NoError(t, checker.Check(context.Background()))
+}
+
+func Test_HTTPCheckerWithOptions_statusOutOfRange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	h := MakeHealth()
+	checker := h.HTTPCheckerWithOptions(HTTPCheckerOptions{URL: srv.URL})
+	assert.Error(t, checker.Check(context.Background()))
+}
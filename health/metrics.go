@@ -0,0 +1,46 @@
+/*
+ * Copyright 2022 Michael Graff.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License")
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Metrics registers Prometheus collectors against registry for per-check
+// up/down state, cumulative failure counts, and check duration, so
+// operators can alert on flap rates and long-running probes instead of
+// only the current boolean exposed by HTTPHandler.
+func (h *Health) Metrics(registry *prometheus.Registry) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.metricsUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "healthcheck",
+		Name:      "check_up",
+		Help:      "1 if the named check is currently healthy, 0 otherwise.",
+	}, []string{"service"})
+	h.metricsFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "healthcheck",
+		Name:      "check_failures_total",
+		Help:      "Total number of failed runs for the named check.",
+	}, []string{"service"})
+	h.metricsDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "healthcheck",
+		Name:      "check_duration_seconds",
+		Help:      "Duration of each check run, in seconds.",
+	}, []string{"service"})
+
+	registry.MustRegister(h.metricsUp, h.metricsFailures, h.metricsDuration)
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2022 Michael Graff.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License")
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Metrics_recordsCheckResults(t *testing.T) {
+	h := MakeHealth()
+	registry := prometheus.NewRegistry()
+	h.Metrics(registry)
+
+	h.AddCheck("svc", false, &testChecker{err: fmt.Errorf("boom")})
+	h.runChecker(context.Background(), dueCheck{service: "svc", checker: h.Checks[0].checker})
+
+	up, err := testutil.GatherAndCount(registry, "healthcheck_check_up")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, up)
+
+	failures, err := testutil.GatherAndCount(registry, "healthcheck_check_failures_total")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, failures)
+
+	assert.Equal(t, float64(0), testutil.ToFloat64(h.metricsUp.WithLabelValues("svc")))
+}